@@ -15,26 +15,51 @@ import (
 
 // WXMch 微信商户
 type WXMch struct {
-	appid     string
-	mchid     string
-	apikey    string
-	client    *utils.WXClient
-	tlsClient *utils.WXClient
+	appid       string
+	mchid       string
+	apikey      string
+	client      *utils.WXClient
+	tlsClient   *utils.WXClient
+	retryPolicy RetryPolicy
 }
 
-func New(appid, mchid, apikey string) *WXMch {
+// Option 配置 WXMch 的可选参数
+type Option func(wx *WXMch)
+
+// WithRetryPolicy 为瞬时网络错误/微信5xx/`return_code=FAIL`的瞬时错误码配置重试策略
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(wx *WXMch) {
+		wx.retryPolicy = policy
+	}
+}
+
+func New(appid, mchid, apikey string, options ...Option) *WXMch {
 	mch := &WXMch{
-		appid:  appid,
-		mchid:  mchid,
-		apikey: apikey,
+		appid:       appid,
+		mchid:       mchid,
+		apikey:      apikey,
+		retryPolicy: DefaultRetryPolicy,
 	}
 
 	mch.client = utils.NewWXClient(utils.WithInsecureSkipVerify())
 	mch.tlsClient = utils.NewWXClient(utils.WithInsecureSkipVerify())
 
+	for _, f := range options {
+		f(mch)
+	}
+
 	return mch
 }
 
+// UseRetryPolicy 返回一个使用指定重试策略的浅拷贝，用于覆盖单次调用的重试行为，
+// 例如 wx.UseRetryPolicy(customPolicy).Order(...).Unify(params)
+func (wx *WXMch) UseRetryPolicy(policy RetryPolicy) *WXMch {
+	clone := *wx
+	clone.retryPolicy = policy
+
+	return &clone
+}
+
 // LoadCertFromP12File load cert from p12(pfx) file
 func (wx *WXMch) LoadCertFromP12File(path string) error {
 	p12, err := ioutil.ReadFile(path)
@@ -234,14 +259,34 @@ func (wx *WXMch) pkcs12ToPem(p12 []byte) (tls.Certificate, error) {
 func (wx *WXMch) post(reqURL string, body utils.WXML, options ...utils.RequestOption) (utils.WXML, error) {
 	body["sign"] = SignWithMD5(body, wx.apikey)
 
-	resp, err := wx.client.PostXML(reqURL, body, options...)
+	var resp utils.WXML
+
+	err := retryDo(wx.retryPolicy, func() error {
+		r, err := wx.client.PostXML(reqURL, body, options...)
+
+		if err != nil {
+			return err
+		}
+
+		if r["return_code"] != ResultSuccess {
+			if !isTransientWXError(r["return_msg"]) {
+				return &permanentError{errors.New(r["return_msg"])}
+			}
+
+			return errors.New(r["return_msg"])
+		}
+
+		resp = r
+
+		return nil
+	})
 
 	if err != nil {
-		return nil, err
-	}
+		if pe, ok := err.(*permanentError); ok {
+			return nil, pe.err
+		}
 
-	if resp["return_code"] != ResultSuccess {
-		return nil, errors.New(resp["return_msg"])
+		return nil, err
 	}
 
 	if err := wx.VerifyWXReply(resp); err != nil {
@@ -254,14 +299,34 @@ func (wx *WXMch) post(reqURL string, body utils.WXML, options ...utils.RequestOp
 func (wx *WXMch) tlsPost(reqURL string, body utils.WXML, options ...utils.RequestOption) (utils.WXML, error) {
 	body["sign"] = SignWithMD5(body, wx.apikey)
 
-	resp, err := wx.tlsClient.PostXML(reqURL, body, options...)
+	var resp utils.WXML
+
+	err := retryDo(wx.retryPolicy, func() error {
+		r, err := wx.tlsClient.PostXML(reqURL, body, options...)
+
+		if err != nil {
+			return err
+		}
+
+		if r["return_code"] != ResultSuccess {
+			if !isTransientWXError(r["return_msg"]) {
+				return &permanentError{errors.New(r["return_msg"])}
+			}
+
+			return errors.New(r["return_msg"])
+		}
+
+		resp = r
+
+		return nil
+	})
 
 	if err != nil {
-		return nil, err
-	}
+		if pe, ok := err.(*permanentError); ok {
+			return nil, pe.err
+		}
 
-	if resp["return_code"] != ResultSuccess {
-		return nil, errors.New(resp["return_msg"])
+		return nil, err
 	}
 
 	if err := wx.VerifyWXReply(resp); err != nil {
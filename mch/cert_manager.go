@@ -0,0 +1,130 @@
+package mch
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultCertRefreshInterval 平台证书默认刷新周期
+const DefaultCertRefreshInterval = 6 * time.Hour
+
+// MaxCertRefreshInterval 平台证书刷新周期上限（刷新周期须小于该值），微信支付要求证书至少每12小时更新一次
+const MaxCertRefreshInterval = 12 * time.Hour
+
+// certFetcher 拉取平台证书的能力，由 *WXMchV3 实现；单独抽出方便测试时注入桩实现
+type certFetcher interface {
+	PlatformCertificates() (map[string]*x509.Certificate, error)
+}
+
+// CertManager 微信支付平台证书管理器
+//
+// 周期性拉取 /v3/certificates 并刷新本地缓存，供签名验签与敏感信息加密使用。
+type CertManager struct {
+	mch      certFetcher
+	interval time.Duration
+
+	mu    sync.RWMutex
+	certs map[string]*x509.Certificate
+}
+
+// NewCertManager 创建证书管理器，interval <= 0 时使用 DefaultCertRefreshInterval
+func NewCertManager(wx *WXMchV3, interval time.Duration) (*CertManager, error) {
+	if interval <= 0 {
+		interval = DefaultCertRefreshInterval
+	}
+
+	if interval >= MaxCertRefreshInterval {
+		return nil, errors.New("cert refresh interval must be less than 12h")
+	}
+
+	m := &CertManager{
+		mch:      wx,
+		interval: interval,
+		certs:    make(map[string]*x509.Certificate),
+	}
+
+	wx.UseCertManager(m)
+
+	return m, nil
+}
+
+// Start 启动后台刷新协程，ctx 取消时退出
+func (m *CertManager) Start(ctx context.Context) error {
+	if err := m.refresh(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := m.refresh(); err != nil {
+					log.Printf("mch: refresh platform certificates failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// refresh 拉取最新平台证书；失败时保留旧缓存，不 panic
+func (m *CertManager) refresh() error {
+	certs, err := m.mch.PlatformCertificates()
+
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for serialNo, cert := range certs {
+		m.certs[serialNo] = cert
+	}
+
+	return nil
+}
+
+// hasCerts 判断本地是否已持有任意可信平台证书
+func (m *CertManager) hasCerts() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.certs) > 0
+}
+
+// GetCertBySerial 按序列号获取缓存的平台证书
+func (m *CertManager) GetCertBySerial(serial string) (*x509.Certificate, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cert, ok := m.certs[serial]
+
+	return cert, ok
+}
+
+// NewestCert 返回生效时间最新的平台证书，用于加密敏感字段
+func (m *CertManager) NewestCert() *x509.Certificate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var newest *x509.Certificate
+
+	for _, cert := range m.certs {
+		if newest == nil || cert.NotBefore.After(newest.NotBefore) {
+			newest = cert
+		}
+	}
+
+	return newest
+}
@@ -0,0 +1,139 @@
+package mch
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCertFetcher 模拟 *WXMchV3.PlatformCertificates，按顺序返回预设的结果
+type fakeCertFetcher struct {
+	mu      sync.Mutex
+	results []func() (map[string]*x509.Certificate, error)
+	calls   int
+}
+
+func (f *fakeCertFetcher) PlatformCertificates() (map[string]*x509.Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := f.calls
+	f.calls++
+
+	if i >= len(f.results) {
+		i = len(f.results) - 1
+	}
+
+	return f.results[i]()
+}
+
+func TestCertManagerRefreshPopulatesCache(t *testing.T) {
+	cert := &x509.Certificate{}
+	fetcher := &fakeCertFetcher{
+		results: []func() (map[string]*x509.Certificate, error){
+			func() (map[string]*x509.Certificate, error) {
+				return map[string]*x509.Certificate{"ABC123": cert}, nil
+			},
+		},
+	}
+
+	m := &CertManager{mch: fetcher, interval: DefaultCertRefreshInterval, certs: make(map[string]*x509.Certificate)}
+
+	if err := m.refresh(); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	got, ok := m.GetCertBySerial("ABC123")
+
+	if !ok {
+		t.Fatal("expected cert ABC123 to be cached")
+	}
+
+	if got != cert {
+		t.Error("cached cert does not match fetched cert")
+	}
+
+	if !m.hasCerts() {
+		t.Error("hasCerts() = false after successful refresh")
+	}
+}
+
+func TestCertManagerStaleCacheRetainedOnError(t *testing.T) {
+	cert := &x509.Certificate{}
+	fetcher := &fakeCertFetcher{
+		results: []func() (map[string]*x509.Certificate, error){
+			func() (map[string]*x509.Certificate, error) {
+				return map[string]*x509.Certificate{"ABC123": cert}, nil
+			},
+			func() (map[string]*x509.Certificate, error) {
+				return nil, errors.New("network error")
+			},
+		},
+	}
+
+	m := &CertManager{mch: fetcher, interval: DefaultCertRefreshInterval, certs: make(map[string]*x509.Certificate)}
+
+	if err := m.refresh(); err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+
+	if err := m.refresh(); err == nil {
+		t.Fatal("expected second refresh to return an error")
+	}
+
+	got, ok := m.GetCertBySerial("ABC123")
+
+	if !ok || got != cert {
+		t.Error("stale cert should remain cached after a failed refresh")
+	}
+}
+
+func TestCertManagerStartStopsOnContextCancel(t *testing.T) {
+	cert := &x509.Certificate{}
+	fetcher := &fakeCertFetcher{
+		results: []func() (map[string]*x509.Certificate, error){
+			func() (map[string]*x509.Certificate, error) {
+				return map[string]*x509.Certificate{"ABC123": cert}, nil
+			},
+		},
+	}
+
+	m := &CertManager{mch: fetcher, interval: time.Millisecond, certs: make(map[string]*x509.Certificate)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := m.Start(ctx); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if !m.hasCerts() {
+		t.Fatal("expected Start to populate cache via the initial synchronous refresh")
+	}
+
+	cancel()
+
+	// the background goroutine should observe ctx.Done() and return; give it a
+	// moment to exit, then check that no further refreshes happen.
+	time.Sleep(20 * time.Millisecond)
+
+	fetcher.mu.Lock()
+	callsAfterCancel := fetcher.calls
+	fetcher.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+
+	fetcher.mu.Lock()
+	callsLater := fetcher.calls
+	fetcher.mu.Unlock()
+
+	if callsLater != callsAfterCancel {
+		t.Errorf("refresh kept running after cancel: calls went from %d to %d", callsAfterCancel, callsLater)
+	}
+
+	if _, ok := m.GetCertBySerial("ABC123"); !ok {
+		t.Error("cache should still be readable after the refresh goroutine stops")
+	}
+}
@@ -0,0 +1,117 @@
+package mch
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// EncryptOAEP 用微信支付平台证书的RSA公钥加密敏感字段（姓名、身份证号、银行账号等）
+//
+// 返回 base64 编码的密文，可直接填入请求体对应字段。
+func EncryptOAEP(plaintext []byte, cert *x509.Certificate) (string, error) {
+	pubKey, ok := cert.PublicKey.(*rsa.PublicKey)
+
+	if !ok {
+		return "", errors.New("platform cert public key is not rsa")
+	}
+
+	ciphertext, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, pubKey, plaintext, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptAEAD 用 APIv3 key 解密 AEAD_AES_256_GCM 密文（平台证书、回调 resource 均为此格式）
+func DecryptAEAD(apikeyV3, associatedData, nonce, ciphertext string) ([]byte, error) {
+	block, err := aes.NewCipher([]byte(apikeyV3))
+
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Open(nil, []byte(nonce), data, []byte(associatedData))
+}
+
+// notifyV3Body 回调通知的公共信封
+type notifyV3Body struct {
+	ID           string `json:"id"`
+	EventType    string `json:"event_type"`
+	ResourceType string `json:"resource_type"`
+	Resource     struct {
+		Algorithm      string `json:"algorithm"`
+		Nonce          string `json:"nonce"`
+		AssociatedData string `json:"associated_data"`
+		Ciphertext     string `json:"ciphertext"`
+	} `json:"resource"`
+}
+
+// DecodeNotifyV3 解密并校验 APIv3 回调通知
+//
+// 先用 wx 校验 Wechatpay-* 请求头签名，再用 APIv3 key 解密 resource.ciphertext，
+// 返回 event_type（如 TRANSACTION.SUCCESS、REFUND.SUCCESS）与解密后的明文 JSON，
+// 调用方可按 event 自行 json.Unmarshal 到对应的事件结构体。
+func (wx *WXMchV3) DecodeNotifyV3(body []byte, header http.Header) (event string, resource []byte, err error) {
+	if err = wx.verify(header, body); err != nil {
+		return "", nil, err
+	}
+
+	notify := new(notifyV3Body)
+
+	if err = json.Unmarshal(body, notify); err != nil {
+		return "", nil, err
+	}
+
+	resource, err = DecryptAEAD(wx.apikeyV3, notify.Resource.AssociatedData, notify.Resource.Nonce, notify.Resource.Ciphertext)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return notify.EventType, resource, nil
+}
+
+// TransactionSuccessEvent 支付成功回调事件（event_type: TRANSACTION.SUCCESS）
+type TransactionSuccessEvent struct {
+	AppID         string       `json:"appid"`
+	MchID         string       `json:"mchid"`
+	OutTradeNo    string       `json:"out_trade_no"`
+	TransactionID string       `json:"transaction_id"`
+	TradeType     string       `json:"trade_type"`
+	TradeState    string       `json:"trade_state"`
+	BankType      string       `json:"bank_type"`
+	Amount        *OrderAmount `json:"amount"`
+}
+
+// RefundSuccessEvent 退款成功回调事件（event_type: REFUND.SUCCESS）
+type RefundSuccessEvent struct {
+	MchID         string        `json:"mchid"`
+	TransactionID string        `json:"transaction_id"`
+	OutTradeNo    string        `json:"out_trade_no"`
+	RefundID      string        `json:"refund_id"`
+	OutRefundNo   string        `json:"out_refund_no"`
+	RefundStatus  string        `json:"refund_status"`
+	Amount        *RefundAmount `json:"amount"`
+}
@@ -0,0 +1,139 @@
+package mch
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func sealAEAD(apikeyV3, associatedData, nonce string, plaintext []byte) (string, error) {
+	block, err := aes.NewCipher([]byte(apikeyV3))
+
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext := gcm.Seal(nil, []byte(nonce), plaintext, []byte(associatedData))
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func selfSignedCert(t *testing.T) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mch-test"},
+		NotBefore:    time.Unix(0, 0),
+		NotAfter:     time.Unix(1<<33, 0),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+
+	return cert, key
+}
+
+func TestEncryptOAEPRoundTrip(t *testing.T) {
+	cert, key := selfSignedCert(t)
+
+	plaintext := []byte("610000199001011234")
+
+	ciphertext, err := EncryptOAEP(plaintext, cert)
+
+	if err != nil {
+		t.Fatalf("EncryptOAEP: %v", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+
+	if err != nil {
+		t.Fatalf("decode ciphertext: %v", err)
+	}
+
+	got, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, data, nil)
+
+	if err != nil {
+		t.Fatalf("DecryptOAEP: %v", err)
+	}
+
+	if string(got) != string(plaintext) {
+		t.Errorf("roundtrip = %q, want %q", got, plaintext)
+	}
+}
+
+func TestEncryptOAEPRejectsNonRSACert(t *testing.T) {
+	cert := &x509.Certificate{PublicKey: "not-an-rsa-key"}
+
+	if _, err := EncryptOAEP([]byte("x"), cert); err == nil {
+		t.Fatal("expected error for non-rsa public key")
+	}
+}
+
+func TestDecryptAEADRoundTrip(t *testing.T) {
+	apikeyV3 := "0123456789abcdef0123456789abcdef"[:32]
+	associatedData := "certificate"
+	nonce := "abcdefghijkl"
+	plaintext := []byte(`{"serial_no":"123"}`)
+
+	ciphertext, err := sealAEAD(apikeyV3, associatedData, nonce, plaintext)
+
+	if err != nil {
+		t.Fatalf("sealAEAD: %v", err)
+	}
+
+	got, err := DecryptAEAD(apikeyV3, associatedData, nonce, ciphertext)
+
+	if err != nil {
+		t.Fatalf("DecryptAEAD: %v", err)
+	}
+
+	if string(got) != string(plaintext) {
+		t.Errorf("roundtrip = %q, want %q", got, plaintext)
+	}
+}
+
+func TestDecryptAEADRejectsMismatchedAssociatedData(t *testing.T) {
+	apikeyV3 := "0123456789abcdef0123456789abcdef"[:32]
+	associatedData := "certificate"
+	nonce := "abcdefghijkl"
+
+	ciphertext, err := sealAEAD(apikeyV3, associatedData, nonce, []byte("payload"))
+
+	if err != nil {
+		t.Fatalf("sealAEAD: %v", err)
+	}
+
+	if _, err := DecryptAEAD(apikeyV3, "tampered", nonce, ciphertext); err == nil {
+		t.Fatal("expected error for mismatched associated data")
+	}
+}
@@ -0,0 +1,82 @@
+package mch
+
+import (
+	"crypto/aes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+)
+
+// notifyV2Envelope APIv2异步通知的公共信封，实际业务数据在 req_info 密文里
+type notifyV2Envelope struct {
+	XMLName    xml.Name `xml:"xml"`
+	ReturnCode string   `xml:"return_code"`
+	Appid      string   `xml:"appid"`
+	MchID      string   `xml:"mch_id"`
+	ReqInfo    string   `xml:"req_info"`
+}
+
+// DecodeNotify 解密 APIv2 异步通知（分账、企业付款等场景）中的 req_info 字段
+//
+// req_info 先 base64 解码，再用 apikey 的 MD5 值（32位小写十六进制）作为密钥做
+// AES-256-ECB 解密并去除 PKCS#7 填充，得到的明文是一段 `<root>...</root>` XML，
+// 调用方可自行反序列化为对应场景的事件结构体。
+func (wx *WXMch) DecodeNotify(body []byte) ([]byte, error) {
+	envelope := new(notifyV2Envelope)
+
+	if err := xml.Unmarshal(body, envelope); err != nil {
+		return nil, err
+	}
+
+	if envelope.ReqInfo == "" {
+		return nil, errors.New("empty req_info")
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.ReqInfo)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sum := md5.Sum([]byte(wx.apikey))
+	key := []byte(hex.EncodeToString(sum[:]))
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	blockSize := block.BlockSize()
+
+	if len(ciphertext) == 0 || len(ciphertext)%blockSize != 0 {
+		return nil, errors.New("invalid req_info length")
+	}
+
+	plain := make([]byte, len(ciphertext))
+
+	for i := 0; i < len(ciphertext); i += blockSize {
+		block.Decrypt(plain[i:i+blockSize], ciphertext[i:i+blockSize])
+	}
+
+	return pkcs7Unpad(plain)
+}
+
+// pkcs7Unpad 去除 PKCS#7 填充
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	n := len(data)
+
+	if n == 0 {
+		return nil, errors.New("empty plaintext")
+	}
+
+	pad := int(data[n-1])
+
+	if pad == 0 || pad > n {
+		return nil, errors.New("invalid pkcs7 padding")
+	}
+
+	return data[:n-pad], nil
+}
@@ -0,0 +1,164 @@
+package mch
+
+import (
+	"encoding/json"
+)
+
+// OrderV3 APIv3下单
+type OrderV3 struct {
+	mch *WXMchV3
+}
+
+// Order returns new OrderV3
+func (wx *WXMchV3) Order() *OrderV3 {
+	return &OrderV3{mch: wx}
+}
+
+// JSAPIParams JSAPI/小程序下单参数
+type JSAPIParams struct {
+	AppID       string       `json:"appid"`
+	MchID       string       `json:"mchid"`
+	Description string       `json:"description"`
+	OutTradeNo  string       `json:"out_trade_no"`
+	NotifyURL   string       `json:"notify_url"`
+	Amount      *OrderAmount `json:"amount"`
+	Payer       *OrderPayer  `json:"payer"`
+}
+
+// OrderAmount 订单金额
+type OrderAmount struct {
+	Total    int    `json:"total"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// OrderPayer 支付者信息
+type OrderPayer struct {
+	OpenID string `json:"openid"`
+}
+
+// PrepayResp 下单应答
+type PrepayResp struct {
+	PrepayID string `json:"prepay_id"`
+}
+
+// JSAPI 用于JSAPI/小程序场景的统一下单
+func (o *OrderV3) JSAPI(params *JSAPIParams) (*PrepayResp, error) {
+	body, err := o.mch.post("/v3/pay/transactions/jsapi", params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(PrepayResp)
+
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// TransactionResp 订单查询应答（节选常用字段）
+type TransactionResp struct {
+	AppID          string       `json:"appid"`
+	MchID          string       `json:"mchid"`
+	OutTradeNo     string       `json:"out_trade_no"`
+	TransactionID  string       `json:"transaction_id"`
+	TradeType      string       `json:"trade_type"`
+	TradeState     string       `json:"trade_state"`
+	TradeStateDesc string       `json:"trade_state_desc"`
+	BankType       string       `json:"bank_type"`
+	Amount         *OrderAmount `json:"amount"`
+}
+
+// QueryByOutTradeNo 以商户订单号查询订单
+func (o *OrderV3) QueryByOutTradeNo(outTradeNo, mchid string) (*TransactionResp, error) {
+	body, err := o.mch.get("/v3/pay/transactions/out-trade-no/"+outTradeNo, map[string]string{"mchid": mchid})
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(TransactionResp)
+
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Close 关闭订单
+func (o *OrderV3) Close(outTradeNo, mchid string) error {
+	_, err := o.mch.post("/v3/pay/transactions/out-trade-no/"+outTradeNo+"/close", map[string]string{"mchid": mchid})
+
+	return err
+}
+
+// RefundV3 APIv3退款
+type RefundV3 struct {
+	mch *WXMchV3
+}
+
+// Refund returns new RefundV3
+func (wx *WXMchV3) Refund() *RefundV3 {
+	return &RefundV3{mch: wx}
+}
+
+// RefundParams 退款申请参数
+type RefundParams struct {
+	TransactionID string        `json:"transaction_id,omitempty"`
+	OutTradeNo    string        `json:"out_trade_no,omitempty"`
+	OutRefundNo   string        `json:"out_refund_no"`
+	Reason        string        `json:"reason,omitempty"`
+	NotifyURL     string        `json:"notify_url,omitempty"`
+	Amount        *RefundAmount `json:"amount"`
+}
+
+// RefundAmount 退款金额
+type RefundAmount struct {
+	Refund   int    `json:"refund"`
+	Total    int    `json:"total"`
+	Currency string `json:"currency"`
+}
+
+// RefundResp 退款申请应答（节选常用字段）
+type RefundResp struct {
+	RefundID    string `json:"refund_id"`
+	OutRefundNo string `json:"out_refund_no"`
+	Status      string `json:"status"`
+}
+
+// Apply 申请退款
+func (r *RefundV3) Apply(params *RefundParams) (*RefundResp, error) {
+	body, err := r.mch.post("/v3/refund/domestic/refunds", params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(RefundResp)
+
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// QueryByOutRefundNo 以商户退款单号查询退款
+func (r *RefundV3) QueryByOutRefundNo(outRefundNo string) (*RefundResp, error) {
+	body, err := r.mch.get("/v3/refund/domestic/refunds/"+outRefundNo, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(RefundResp)
+
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
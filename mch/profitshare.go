@@ -0,0 +1,243 @@
+package mch
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/shenghui0779/gochat/utils"
+)
+
+// 分账(APIv2)相关接口地址
+const (
+	ProfitSharingAddReceiverURL    = "https://api.mch.weixin.qq.com/pay/profitsharingaddreceiver"
+	ProfitSharingRemoveReceiverURL = "https://api.mch.weixin.qq.com/pay/profitsharingremovereceiver"
+	ProfitSharingURL               = "https://api.mch.weixin.qq.com/secapi/pay/profitsharing"
+	ProfitSharingMultiURL          = "https://api.mch.weixin.qq.com/secapi/pay/multiprofitsharing"
+	ProfitSharingQueryURL          = "https://api.mch.weixin.qq.com/pay/profitsharingquery"
+	ProfitSharingFinishURL         = "https://api.mch.weixin.qq.com/secapi/pay/profitsharingfinish"
+	ProfitSharingUnfreezeURL       = "https://api.mch.weixin.qq.com/secapi/pay/profitsharingunfreeze"
+	ProfitSharingBalanceURL        = "https://api.mch.weixin.qq.com/pay/getbalance"
+)
+
+var errProfitshareV3NotConfigured = errors.New("mch: profitshare v3 client not configured, call WithV3 first")
+
+// Profitshare 微信支付分账
+type Profitshare struct {
+	mch     *WXMch
+	mchV3   *WXMchV3
+	options []utils.RequestOption
+}
+
+// Profitshare returns new profitshare
+func (wx *WXMch) Profitshare(options ...utils.RequestOption) *Profitshare {
+	return &Profitshare{
+		mch:     wx,
+		options: options,
+	}
+}
+
+// WithV3 让分账操作改用 APIv3 网关（/v3/profitsharing/*）
+func (p *Profitshare) WithV3(mchV3 *WXMchV3) *Profitshare {
+	p.mchV3 = mchV3
+
+	return p
+}
+
+// ProfitshareReceiver 分账接收方
+type ProfitshareReceiver struct {
+	Type         string `json:"type"` // MERCHANT_ID / PERSONAL_OPENID
+	Account      string `json:"account"`
+	Name         string `json:"name,omitempty"`
+	RelationType string `json:"relation_type"`
+}
+
+// AddReceiver 添加分账接收方（APIv2：pay/profitsharingaddreceiver）
+func (p *Profitshare) AddReceiver(receiver *ProfitshareReceiver) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":    p.mch.mchid,
+		"appid":     p.mch.appid,
+		"nonce_str": utils.Nonce(16),
+		"receiver":  mustJSON(receiver),
+	}
+
+	return p.mch.post(ProfitSharingAddReceiverURL, body, p.options...)
+}
+
+// DeleteReceiver 删除分账接收方
+func (p *Profitshare) DeleteReceiver(receiver *ProfitshareReceiver) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":    p.mch.mchid,
+		"appid":     p.mch.appid,
+		"nonce_str": utils.Nonce(16),
+		"receiver":  mustJSON(receiver),
+	}
+
+	return p.mch.post(ProfitSharingRemoveReceiverURL, body, p.options...)
+}
+
+// ShareReceiver 本次分账的接收方明细
+type ShareReceiver struct {
+	Type        string `json:"type"`
+	Account     string `json:"account"`
+	Amount      int    `json:"amount"`
+	Description string `json:"description"`
+}
+
+// SingleShare 单次分账（APIv2：secapi/pay/profitsharing）
+func (p *Profitshare) SingleShare(outTradeNo, outOrderNo string, receivers []*ShareReceiver) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":         p.mch.mchid,
+		"appid":          p.mch.appid,
+		"nonce_str":      utils.Nonce(16),
+		"transaction_id": outTradeNo,
+		"out_order_no":   outOrderNo,
+		"receivers":      mustJSON(receivers),
+	}
+
+	return p.mch.tlsPost(ProfitSharingURL, body, p.options...)
+}
+
+// MultiShare 多次分账（APIv2：secapi/pay/multiprofitsharing）
+func (p *Profitshare) MultiShare(outTradeNo, outOrderNo string, receivers []*ShareReceiver) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":         p.mch.mchid,
+		"appid":          p.mch.appid,
+		"nonce_str":      utils.Nonce(16),
+		"transaction_id": outTradeNo,
+		"out_order_no":   outOrderNo,
+		"receivers":      mustJSON(receivers),
+	}
+
+	return p.mch.tlsPost(ProfitSharingMultiURL, body, p.options...)
+}
+
+// QueryShare 查询分账结果
+func (p *Profitshare) QueryShare(outTradeNo, outOrderNo string) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":         p.mch.mchid,
+		"appid":          p.mch.appid,
+		"nonce_str":      utils.Nonce(16),
+		"transaction_id": outTradeNo,
+		"out_order_no":   outOrderNo,
+	}
+
+	return p.mch.post(ProfitSharingQueryURL, body, p.options...)
+}
+
+// FinishShare 完结分账
+func (p *Profitshare) FinishShare(outTradeNo, outOrderNo, description string) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":         p.mch.mchid,
+		"appid":          p.mch.appid,
+		"nonce_str":      utils.Nonce(16),
+		"transaction_id": outTradeNo,
+		"out_order_no":   outOrderNo,
+		"description":    description,
+	}
+
+	return p.mch.tlsPost(ProfitSharingFinishURL, body, p.options...)
+}
+
+// UnfreezeRemaining 解冻剩余资金
+func (p *Profitshare) UnfreezeRemaining(outTradeNo, outOrderNo, description string) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":         p.mch.mchid,
+		"appid":          p.mch.appid,
+		"nonce_str":      utils.Nonce(16),
+		"transaction_id": outTradeNo,
+		"out_order_no":   outOrderNo,
+		"description":    description,
+	}
+
+	return p.mch.tlsPost(ProfitSharingUnfreezeURL, body, p.options...)
+}
+
+// QueryBalance 查询分账待分金额
+func (p *Profitshare) QueryBalance(outTradeNo string) (utils.WXML, error) {
+	body := utils.WXML{
+		"mch_id":         p.mch.mchid,
+		"appid":          p.mch.appid,
+		"nonce_str":      utils.Nonce(16),
+		"transaction_id": outTradeNo,
+	}
+
+	return p.mch.post(ProfitSharingBalanceURL, body, p.options...)
+}
+
+// ShareReceiverV3 APIv3 分账接收方明细
+type ShareReceiverV3 struct {
+	Type        string `json:"type"`
+	Account     string `json:"account"`
+	Amount      int    `json:"amount"`
+	Description string `json:"description"`
+}
+
+// ShareParamsV3 APIv3 请求分账参数
+type ShareParamsV3 struct {
+	AppID         string             `json:"appid"`
+	TransactionID string             `json:"transaction_id"`
+	OutOrderNo    string             `json:"out_order_no"`
+	Receivers     []*ShareReceiverV3 `json:"receivers"`
+	Finish        bool               `json:"finish"`
+}
+
+// ShareV3 请求分账（APIv3：/v3/profitsharing/orders）
+func (p *Profitshare) ShareV3(params *ShareParamsV3) ([]byte, error) {
+	if p.mchV3 == nil {
+		return nil, errProfitshareV3NotConfigured
+	}
+
+	return p.mchV3.post("/v3/profitsharing/orders", params)
+}
+
+// ProfitShareSuccessEvent 分账动账回调事件（event_type: PROFITSHARING.FINISH 等）
+type ProfitShareSuccessEvent struct {
+	MchID         string `json:"mchid"`
+	TransactionID string `json:"transaction_id"`
+	OutOrderNo    string `json:"out_order_no"`
+	SubMchID      string `json:"sub_mchid,omitempty"`
+}
+
+// ProfitShareNotifyV2Event APIv2 分账回调（req_info 解密后的明文）
+type ProfitShareNotifyV2Event struct {
+	XMLName       xml.Name `xml:"root"`
+	MchID         string   `xml:"mch_id"`
+	TransactionID string   `xml:"transaction_id"`
+	OutOrderNo    string   `xml:"out_order_no"`
+	SubMchID      string   `xml:"sub_mch_id,omitempty"`
+}
+
+// EventProfitShareNotifyV2 APIv2 分账回调固定事件名，APIv3 回调的事件名来自应答本身的 event_type
+const EventProfitShareNotifyV2 = "profitsharing_notify"
+
+// DecodeProfitShareNotify 解密分账回调
+//
+// 已通过 WithV3 配置 APIv3 网关时走 DecodeNotifyV3（校验 Wechatpay-* 签名后解密
+// JSON + AEAD_AES_256_GCM），否则按 APIv2 的 req_info 信封解密（AES-256-ECB），
+// 返回的明文可反序列化为 ProfitShareNotifyV2Event；APIv2 分支只做解密，不校验签名，
+// 也不核对信封中的 appid/mch_id。
+func (p *Profitshare) DecodeProfitShareNotify(body []byte, header http.Header) (event string, resource []byte, err error) {
+	if p.mchV3 != nil {
+		return p.mchV3.DecodeNotifyV3(body, header)
+	}
+
+	resource, err = p.mch.DecodeNotify(body)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return EventProfitShareNotifyV2, resource, nil
+}
+
+func mustJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
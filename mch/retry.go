@@ -0,0 +1,106 @@
+package mch
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RetryPolicy 请求重试策略
+//
+// out_trade_no/out_refund_no/partner_trade_no 等幂等键由调用方在构造请求体时一次性
+// 生成，重试时复用同一份请求体，因此重试不会产生重复的下单/退款/转账——微信服务端的
+// 幂等性由相同的业务单号保证。
+type RetryPolicy struct {
+	MaxAttempts  int           // 最大尝试次数（含首次），<=1 表示不重试
+	InitialDelay time.Duration // 首次重试前的等待时间
+	MaxDelay     time.Duration // 单次等待时间上限
+	Jitter       float64       // 抖动比例，0~1，实际等待时间在 [delay*(1-Jitter), delay] 之间
+}
+
+// DefaultRetryPolicy 默认重试策略：最多重试2次，初始延迟200ms，封顶2s，带20%抖动
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  3,
+	InitialDelay: 200 * time.Millisecond,
+	MaxDelay:     2 * time.Second,
+	Jitter:       0.2,
+}
+
+// transientReturnMsg 微信返回的、判定为瞬时错误可重试的 return_msg 关键字
+var transientReturnMsg = []string{
+	"SYSTEMERROR",
+	"系统繁忙",
+	"BIZERR_NEED_RETRY",
+}
+
+func isTransientWXError(msg string) bool {
+	for _, kw := range transientReturnMsg {
+		if strings.Contains(msg, kw) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// permanentError 包裹一个不应重试的终态错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// retryDo 按策略执行 fn，fn 返回 *permanentError 时立即终止重试
+func retryDo(policy RetryPolicy, fn func() error) error {
+	attempts := policy.MaxAttempts
+
+	if attempts <= 0 {
+		attempts = 1
+	}
+
+	delay := policy.InitialDelay
+
+	var err error
+
+	for i := 0; i < attempts; i++ {
+		err = fn()
+
+		if err == nil {
+			return nil
+		}
+
+		if _, ok := err.(*permanentError); ok {
+			return err
+		}
+
+		if i == attempts-1 {
+			break
+		}
+
+		time.Sleep(jitterDelay(delay, policy.Jitter))
+
+		delay *= 2
+
+		if policy.MaxDelay > 0 && delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+	}
+
+	return err
+}
+
+func jitterDelay(delay time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || delay <= 0 {
+		return delay
+	}
+
+	min := float64(delay) * (1 - jitter)
+
+	return time.Duration(min + rand.Float64()*(float64(delay)-min))
+}
@@ -0,0 +1,123 @@
+package mch
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsTransientWXError(t *testing.T) {
+	cases := []struct {
+		msg  string
+		want bool
+	}{
+		{"systemerror", false},
+		{"SYSTEMERROR", true},
+		{"系统繁忙，请稍后再试", true},
+		{"BIZERR_NEED_RETRY", true},
+		{"noauth", false},
+		{"", false},
+	}
+
+	for _, c := range cases {
+		if got := isTransientWXError(c.msg); got != c.want {
+			t.Errorf("isTransientWXError(%q) = %v, want %v", c.msg, got, c.want)
+		}
+	}
+}
+
+func TestJitterDelay(t *testing.T) {
+	delay := 200 * time.Millisecond
+
+	for i := 0; i < 100; i++ {
+		got := jitterDelay(delay, 0.2)
+
+		if got > delay || got < time.Duration(float64(delay)*0.8) {
+			t.Fatalf("jitterDelay out of range: %v", got)
+		}
+	}
+
+	if got := jitterDelay(delay, 0); got != delay {
+		t.Errorf("jitterDelay with zero jitter = %v, want %v", got, delay)
+	}
+
+	if got := jitterDelay(0, 0.2); got != 0 {
+		t.Errorf("jitterDelay with zero delay = %v, want 0", got)
+	}
+}
+
+func TestRetryDoSucceedsWithoutRetry(t *testing.T) {
+	calls := 0
+
+	err := retryDo(DefaultRetryPolicy, func() error {
+		calls++
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRetryDoRetriesTransientError(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Jitter: 0}
+
+	err := retryDo(policy, func() error {
+		calls++
+
+		if calls < 3 {
+			return errors.New("SYSTEMERROR")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRetryDoStopsOnPermanentError(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Jitter: 0}
+
+	err := retryDo(policy, func() error {
+		calls++
+		return &permanentError{errors.New("noauth")}
+	})
+
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1 (no retry after permanent error)", calls)
+	}
+}
+
+func TestRetryDoExhaustsAttempts(t *testing.T) {
+	calls := 0
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Jitter: 0}
+
+	err := retryDo(policy, func() error {
+		calls++
+		return errors.New("SYSTEMERROR")
+	})
+
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
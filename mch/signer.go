@@ -0,0 +1,112 @@
+package mch
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/shenghui0779/gochat/utils"
+)
+
+// Signer 商户私钥签名器，让 KMS/HSM 托管的私钥可以替代本地磁盘私钥
+type Signer interface {
+	// Sign 对 SHA256 摘要签名，返回 PKCS#1 v1.5 签名结果
+	Sign(digest []byte) ([]byte, error)
+	// SerialNo 返回该签名器对应商户证书的序列号
+	SerialNo() string
+}
+
+// fileSigner 默认的文件私钥签名器，等价于此前直接从磁盘读取PEM私钥的行为
+type fileSigner struct {
+	serialNo string
+	key      *rsa.PrivateKey
+}
+
+// NewFileSigner 用已解析好的商户私钥构造默认签名器
+func NewFileSigner(serialNo string, key *rsa.PrivateKey) Signer {
+	return &fileSigner{serialNo: serialNo, key: key}
+}
+
+// LoadSignerFromPemFile 从PKCS#8 PEM文件加载商户私钥，构造默认签名器
+func LoadSignerFromPemFile(serialNo, path string) (Signer, error) {
+	b, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadSignerFromPemBlock(serialNo, b)
+}
+
+// LoadSignerFromPemBlock 从PKCS#8 PEM编码数据加载商户私钥，构造默认签名器
+func LoadSignerFromPemBlock(serialNo string, pemBlock []byte) (Signer, error) {
+	block, _ := pem.Decode(pemBlock)
+
+	if block == nil {
+		return nil, errors.New("invalid merchant private key pem block")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+
+	if !ok {
+		return nil, errors.New("merchant private key is not a rsa private key")
+	}
+
+	return NewFileSigner(serialNo, rsaKey), nil
+}
+
+func (s *fileSigner) Sign(digest []byte) ([]byte, error) {
+	return rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest)
+}
+
+func (s *fileSigner) SerialNo() string {
+	return s.serialNo
+}
+
+// cryptoSigner 把 Signer 适配成标准库 crypto.Signer，用作 tls.Certificate 的私钥
+type cryptoSigner struct {
+	signer Signer
+	public crypto.PublicKey
+}
+
+func (s *cryptoSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign 校验 opts 与 Signer 唯一支持的方案（PKCS#1 v1.5 + SHA-256）一致，避免
+// TLS 1.3 协商出 RSA-PSS 等方案时静默签出错误的签名
+func (s *cryptoSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.SHA256 {
+		return nil, fmt.Errorf("cryptoSigner: unsupported hash %v, signer only supports SHA-256", opts.HashFunc())
+	}
+
+	if _, isPSS := opts.(*rsa.PSSOptions); isPSS {
+		return nil, errors.New("cryptoSigner: RSA-PSS is not supported, signer only supports PKCS#1 v1.5")
+	}
+
+	return s.signer.Sign(digest)
+}
+
+// LoadCertFromSigner 用证书链 + 外部 Signer 构造mTLS客户端证书，是 LoadCertFromPemFile
+// 系列方法在私钥由 KMS/HSM 托管时的替代
+func (wx *WXMch) LoadCertFromSigner(certDER [][]byte, pub crypto.PublicKey, signer Signer) error {
+	wx.tlsClient = utils.NewWXClient(utils.WithCertificates(tls.Certificate{
+		Certificate: certDER,
+		PrivateKey:  &cryptoSigner{signer: signer, public: pub},
+	}), utils.WithInsecureSkipVerify())
+
+	return nil
+}
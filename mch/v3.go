@@ -0,0 +1,388 @@
+package mch
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/shenghui0779/gochat/utils"
+)
+
+// V3BaseURL 微信支付APIv3网关
+const V3BaseURL = "https://api.mch.weixin.qq.com"
+
+// WXMchV3 微信商户(APIv3)
+//
+// 与 WXMch 并行存在：APIv2 走 XML + MD5/HMAC-SHA256 签名，APIv3 走 JSON + RSA-SHA256 签名。
+// APIv3 请求/应答都需要直接读写 HTTP 头（Authorization、Wechatpay-*），utils.WXClient
+// 目前只封装了 XML 报文收发，因此这里直接基于 net/http 实现，不依赖 WXClient。
+type WXMchV3 struct {
+	mchid       string
+	apikeyV3    string
+	signer      Signer
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+
+	mu    sync.RWMutex
+	certs map[string]*x509.Certificate // 平台证书缓存，key 为证书序列号
+
+	certManager *CertManager // 若设置，验签只从证书管理器的自动刷新缓存中查找，不再查 certs
+}
+
+// UseCertManager 让该实例的验签改为使用 CertManager 维护的自动刷新证书缓存
+func (wx *WXMchV3) UseCertManager(m *CertManager) {
+	wx.certManager = m
+}
+
+// OptionV3 配置 WXMchV3 的可选参数
+type OptionV3 func(wx *WXMchV3)
+
+// WithV3RetryPolicy 为瞬时网络错误/5xx/WeChat瞬时错误码配置重试策略
+func WithV3RetryPolicy(policy RetryPolicy) OptionV3 {
+	return func(wx *WXMchV3) {
+		wx.retryPolicy = policy
+	}
+}
+
+// NewV3 生成一个APIv3商户实例，签名委托给 signer（默认文件私钥签名器，或 KMS/HSM 实现）
+func NewV3(mchid, apikeyV3 string, signer Signer, options ...OptionV3) *WXMchV3 {
+	wx := &WXMchV3{
+		mchid:    mchid,
+		apikeyV3: apikeyV3,
+		signer:   signer,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+		certs:       make(map[string]*x509.Certificate),
+		retryPolicy: DefaultRetryPolicy,
+	}
+
+	for _, f := range options {
+		f(wx)
+	}
+
+	return wx
+}
+
+// UseRetryPolicy 返回一个使用指定重试策略的浅拷贝，用于覆盖单次调用的重试行为
+func (wx *WXMchV3) UseRetryPolicy(policy RetryPolicy) *WXMchV3 {
+	clone := *wx
+	clone.retryPolicy = policy
+
+	return &clone
+}
+
+// SetSigner 替换商户私钥签名器，用于切换到 KMS/HSM 实现
+func (wx *WXMchV3) SetSigner(signer Signer) {
+	wx.signer = signer
+}
+
+// authorization 构造 Authorization: WECHATPAY2-SHA256-RSA2048 ... 请求头
+func (wx *WXMchV3) authorization(method, uri, timestamp, nonce, body string) (string, error) {
+	if wx.signer == nil {
+		return "", errors.New("merchant signer not set")
+	}
+
+	signStr := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", method, uri, timestamp, nonce, body)
+
+	h := sha256.Sum256([]byte(signStr))
+
+	sig, err := wx.signer.Sign(h[:])
+
+	if err != nil {
+		return "", err
+	}
+
+	signature := base64.StdEncoding.EncodeToString(sig)
+
+	return fmt.Sprintf(`WECHATPAY2-SHA256-RSA2048 mchid="%s",nonce_str="%s",timestamp="%s",serial_no="%s",signature="%s"`,
+		wx.mchid, nonce, timestamp, wx.signer.SerialNo(), signature), nil
+}
+
+// do 发起一次APIv3 HTTP请求，返回原始应答体与应答头；5xx视为瞬时错误，4xx视为终态错误
+func (wx *WXMchV3) do(method, uri string, body []byte) ([]byte, http.Header, error) {
+	nonce := utils.Nonce(16)
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+
+	auth, err := wx.authorization(method, uri, timestamp, nonce, string(body))
+
+	if err != nil {
+		return nil, nil, &permanentError{err}
+	}
+
+	var reqBody *bytes.Reader
+
+	if len(body) > 0 {
+		reqBody = bytes.NewReader(body)
+	} else {
+		reqBody = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, V3BaseURL+uri, reqBody)
+
+	if err != nil {
+		return nil, nil, &permanentError{err}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", auth)
+
+	resp, err := wx.httpClient.Do(req)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, nil, fmt.Errorf("wechatpay apiv3 server error: %d %s", resp.StatusCode, respBody)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, nil, &permanentError{fmt.Errorf("wechatpay apiv3 request failed: %d %s", resp.StatusCode, respBody)}
+	}
+
+	return respBody, resp.Header, nil
+}
+
+// post 发起一次 APIv3 JSON 请求，并校验应答签名
+func (wx *WXMchV3) post(uri string, params interface{}) ([]byte, error) {
+	return wx.postVerified(uri, params, true)
+}
+
+// postVerified 发起一次 APIv3 JSON 请求；verify=false 时跳过应答签名校验，仅供
+// 本地（或委托的 CertManager）尚无任何可信平台证书时的首次 /v3/certificates 拉取使用，
+// 按微信支付规范信任该次应答完成引导；此后的每次刷新都已有缓存可验，照常校验。
+func (wx *WXMchV3) postVerified(uri string, params interface{}, verify bool) ([]byte, error) {
+	body := []byte("")
+
+	if params != nil {
+		b, err := json.Marshal(params)
+
+		if err != nil {
+			return nil, err
+		}
+
+		body = b
+	}
+
+	var resp []byte
+
+	err := retryDo(wx.retryPolicy, func() error {
+		r, header, err := wx.do(http.MethodPost, uri, body)
+
+		if err != nil {
+			return err
+		}
+
+		if verify {
+			if err := wx.verify(header, r); err != nil {
+				return &permanentError{err}
+			}
+		}
+
+		resp = r
+
+		return nil
+	})
+
+	if err != nil {
+		if pe, ok := err.(*permanentError); ok {
+			return nil, pe.err
+		}
+
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// get 发起一次 APIv3 JSON GET 请求，并校验应答签名
+func (wx *WXMchV3) get(uri string, query map[string]string) ([]byte, error) {
+	if len(query) > 0 {
+		values := url.Values{}
+
+		for k, v := range query {
+			values.Set(k, v)
+		}
+
+		uri += "?" + values.Encode()
+	}
+
+	var resp []byte
+
+	err := retryDo(wx.retryPolicy, func() error {
+		r, header, err := wx.do(http.MethodGet, uri, nil)
+
+		if err != nil {
+			return err
+		}
+
+		if err := wx.verify(header, r); err != nil {
+			return &permanentError{err}
+		}
+
+		resp = r
+
+		return nil
+	})
+
+	if err != nil {
+		if pe, ok := err.(*permanentError); ok {
+			return nil, pe.err
+		}
+
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// verify 校验微信应答签名：Wechatpay-Timestamp / Wechatpay-Nonce / Wechatpay-Serial / Wechatpay-Signature
+func (wx *WXMchV3) verify(header http.Header, body []byte) error {
+	serial := header.Get("Wechatpay-Serial")
+	timestamp := header.Get("Wechatpay-Timestamp")
+	nonce := header.Get("Wechatpay-Nonce")
+	signature := header.Get("Wechatpay-Signature")
+
+	if serial == "" || timestamp == "" || nonce == "" || signature == "" {
+		return errors.New("missing wechatpay signature headers")
+	}
+
+	cert, ok := wx.GetCertBySerial(serial)
+
+	if !ok {
+		return fmt.Errorf("platform cert not found, serial: %s", serial)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+
+	if err != nil {
+		return err
+	}
+
+	signStr := fmt.Sprintf("%s\n%s\n%s\n", timestamp, nonce, string(body))
+	h := sha256.Sum256([]byte(signStr))
+
+	pubKey, ok := cert.PublicKey.(*rsa.PublicKey)
+
+	if !ok {
+		return errors.New("platform cert public key is not rsa")
+	}
+
+	return rsa.VerifyPKCS1v15(pubKey, crypto.SHA256, h[:], sig)
+}
+
+// GetCertBySerial 按序列号获取已缓存的平台证书
+func (wx *WXMchV3) GetCertBySerial(serial string) (*x509.Certificate, bool) {
+	if wx.certManager != nil {
+		return wx.certManager.GetCertBySerial(serial)
+	}
+
+	wx.mu.RLock()
+	defer wx.mu.RUnlock()
+
+	cert, ok := wx.certs[serial]
+
+	return cert, ok
+}
+
+// hasCerts 判断本地（或委托的 CertManager）是否已持有任意可信平台证书
+func (wx *WXMchV3) hasCerts() bool {
+	if wx.certManager != nil {
+		return wx.certManager.hasCerts()
+	}
+
+	wx.mu.RLock()
+	defer wx.mu.RUnlock()
+
+	return len(wx.certs) > 0
+}
+
+// v3CertificateResp /v3/certificates 应答
+type v3CertificateResp struct {
+	Data []struct {
+		SerialNo           string `json:"serial_no"`
+		EffectiveTime      string `json:"effective_time"`
+		ExpireTime         string `json:"expire_time"`
+		EncryptCertificate struct {
+			Algorithm      string `json:"algorithm"`
+			Nonce          string `json:"nonce"`
+			AssociatedData string `json:"associated_data"`
+			Ciphertext     string `json:"ciphertext"`
+		} `json:"encrypt_certificate"`
+	} `json:"data"`
+}
+
+// PlatformCertificates 获取并缓存微信支付平台证书，返回值以微信应答中的 serial_no
+// 字符串为 key——该序列号必须原样保留，不能从解析后的证书重新推导（x509.Certificate
+// 的 SerialNumber 是大整数，Text(16) 得到的十六进制大小写/补零方式和微信原始的
+// serial_no、Wechatpay-Serial 头并不一致，用它重新生成 key 会导致后续验签查不到证书）。
+//
+// 首次调用时本地尚无可信证书，跳过验签、直接信任应答结果完成引导；此后本地已有缓存，
+// 每次刷新都会像其他请求一样正常校验 Wechatpay-Signature。
+func (wx *WXMchV3) PlatformCertificates() (map[string]*x509.Certificate, error) {
+	body, err := wx.postVerified("/v3/certificates", nil, wx.hasCerts())
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(v3CertificateResp)
+
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, err
+	}
+
+	certs := make(map[string]*x509.Certificate, len(resp.Data))
+
+	wx.mu.Lock()
+	defer wx.mu.Unlock()
+
+	for _, item := range resp.Data {
+		plain, err := DecryptAEAD(wx.apikeyV3, item.EncryptCertificate.AssociatedData, item.EncryptCertificate.Nonce, item.EncryptCertificate.Ciphertext)
+
+		if err != nil {
+			return nil, err
+		}
+
+		block, _ := pem.Decode(plain)
+
+		if block == nil {
+			return nil, errors.New("invalid platform cert pem block")
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		wx.certs[item.SerialNo] = cert
+		certs[item.SerialNo] = cert
+	}
+
+	return certs, nil
+}